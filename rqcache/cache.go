@@ -3,36 +3,113 @@ package rqcache
 import (
 	"rqcache/lru"
 	"sync"
+	"time"
 )
 
-// 定义缓存结构体
+// 定义缓存结构体，对 lru.Policy 进行了一层带锁封装，并负责识别按 key 设置的 TTL
 type cache struct {
 	mu         sync.Mutex // 互斥锁
-	lru        *lru.Cache
+	policy     lru.Policy
 	cacheBytes int64
+	newPolicy  lru.PolicyFactory // 可选，未设置时回退到 lru.NewLRUPolicy
 }
 
-// 添加缓存
-func (c *cache) add(key string, value ByteView) {
+// add 写入一个键值对，ttl 为 0 表示永不过期，version 是该值的单调递增版本号，
+// 供写失效通知（见 invalidate）比较新旧。如果本地已有一条版本更新的记录，
+// 本次写入会被跳过：这种情况出现在一次较慢的 Getter 加载和一次并发的
+// Update/Remove 竞争同一个 key 时，避免前者用过时的数据覆盖后者。
+func (c *cache) add(key string, value ByteView, ttl time.Duration, version int64) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
-	if c.lru == nil {
-		c.lru = lru.New(c.cacheBytes, nil)
+	if c.policy == nil {
+		c.policy = c.factory()(c.cacheBytes, nil)
 	}
-	c.lru.Add(key, value)
+	if existing, ok := c.policy.Peek(key); ok && existing.(ttlValue).version > version {
+		return
+	}
+	v := ttlValue{ByteView: value, version: version}
+	if ttl > 0 {
+		v.expiresAt = time.Now().Add(ttl)
+	}
+	c.policy.Add(key, v)
+}
+
+// getWithExpiry 获取缓存，并额外返回该条目的过期时间（零值表示永不过期）与
+// 版本号。命中但已经过期的条目会被立即删除并视为未命中，而不是等待被容量淘汰。
+func (c *cache) getWithExpiry(key string) (value ByteView, expiresAt time.Time, version int64, ok bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.policy == nil {
+		return
+	}
+
+	v, ok := c.policy.Get(key)
+	if !ok {
+		return ByteView{}, time.Time{}, 0, false
+	}
+	tv := v.(ttlValue)
+	if tv.expired(time.Now()) {
+		c.policy.Remove(key)
+		return ByteView{}, time.Time{}, 0, false
+	}
+	return tv.ByteView, tv.expiresAt, tv.version, true
+}
+
+// remove 无条件移除 key 对应的缓存条目，用于显式的 Group.Remove
+func (c *cache) remove(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.policy == nil {
+		return
+	}
+	c.policy.Remove(key)
 }
 
-// 获取缓存
-func (c *cache) get(key string) (value ByteView, ok bool) {
+// invalidate 处理来自同伴节点的写失效通知：只有当本地条目记录的 version 不晚
+// 于（<=）传入的 version 时才移除它，避免一次滞后到达的失效通知误删了本地
+// 随后已经写入的更高版本数据；key 不存在或本地版本更新时都是 no-op。
+func (c *cache) invalidate(key string, version int64) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
-	if c.lru == nil {
+	if c.policy == nil {
 		return
 	}
+	v, ok := c.policy.Get(key)
+	if !ok {
+		return
+	}
+	if v.(ttlValue).version <= version {
+		c.policy.Remove(key)
+	}
+}
 
-	if v, ok := c.lru.Get(key); ok {
-		return v.(ByteView), ok
+// sweepExpired 抽取至多 n 个 key 主动检查并清除已过期的条目，不依赖读取触发，
+// 用于 Group 的后台定期清理（Redis 式主动过期）
+func (c *cache) sweepExpired(n int) (evicted int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.policy == nil {
+		return 0
 	}
 
-	return
+	now := time.Now()
+	for _, key := range c.policy.Sample(n) {
+		v, ok := c.policy.Peek(key)
+		if !ok {
+			continue
+		}
+		if tv := v.(ttlValue); tv.expired(now) {
+			c.policy.Remove(key)
+			evicted++
+		}
+	}
+	return evicted
+}
+
+// factory 返回用于延迟初始化 policy 的工厂函数
+func (c *cache) factory() lru.PolicyFactory {
+	if c.newPolicy != nil {
+		return c.newPolicy
+	}
+	return lru.NewLRUPolicy
 }
@@ -0,0 +1,101 @@
+// Package consistenthash 实现了一致性哈希算法，用于在一组同伴节点之间分配 key，
+// 并支持按权重分配虚拟节点数量，以及按顺时针顺序遍历候选节点以实现带负载边界
+// 的选择策略。
+package consistenthash
+
+import (
+	"hash/crc32"
+	"sort"
+	"strconv"
+)
+
+// Hash 将字节串映射为 uint32，以便在一致性哈希环上定位
+type Hash func(data []byte) uint32
+
+// Map 维护一致性哈希环：一组经过哈希排序的虚拟节点，以及虚拟节点到真实节点
+// 名称的映射
+type Map struct {
+	hash     Hash
+	replicas int // 每个节点默认的虚拟节点数量（权重为 1 时）
+	keys     []int
+	hashMap  map[int]string
+}
+
+// New 创建一个 Map，replicas 为权重为 1 的节点默认拥有的虚拟节点数量，
+// fn 为空时使用 crc32.ChecksumIEEE
+func New(replicas int, fn Hash) *Map {
+	m := &Map{
+		replicas: replicas,
+		hash:     fn,
+		hashMap:  make(map[int]string),
+	}
+	if m.hash == nil {
+		m.hash = crc32.ChecksumIEEE
+	}
+	return m
+}
+
+// Add 使用默认权重（1）添加一组节点，等价于对每个节点调用 AddWeighted(node, 1)
+func (m *Map) Add(nodes ...string) {
+	for _, node := range nodes {
+		m.AddWeighted(node, 1)
+	}
+}
+
+// AddWeighted 添加一个节点，其虚拟节点数量为 replicas*weight。weight 越大，
+// 该节点在环上占据的虚拟节点越多，从而按比例分担更多的 key，适合节点之间
+// 硬件规格（CPU/内存/带宽）不一致的场景。weight <= 0 时按 1 处理。
+func (m *Map) AddWeighted(node string, weight int) {
+	if weight <= 0 {
+		weight = 1
+	}
+	n := m.replicas * weight
+	for i := 0; i < n; i++ {
+		hash := int(m.hash([]byte(strconv.Itoa(i) + node)))
+		m.keys = append(m.keys, hash)
+		m.hashMap[hash] = node
+	}
+	sort.Ints(m.keys)
+}
+
+// Get 返回顺时针方向上离 key 的哈希值最近的真实节点
+func (m *Map) Get(key string) string {
+	if len(m.keys) == 0 {
+		return ""
+	}
+
+	hash := int(m.hash([]byte(key)))
+	idx := sort.Search(len(m.keys), func(i int) bool {
+		return m.keys[i] >= hash
+	})
+
+	return m.hashMap[m.keys[idx%len(m.keys)]]
+}
+
+// Walk 从 key 哈希值所在位置开始，按顺时针顺序依次把环上遇到的真实节点（每个
+// 真实节点只访问一次）交给 visit 处理，直到 visit 返回 true（表示已经选中）或
+// 遍历完所有不同的真实节点为止。这使得调用方可以在命中的虚拟节点所属真实
+// 节点不满足条件时（例如带负载边界策略下节点过载），继续尝试环上的下一个
+// 候选节点，而不必了解环的内部结构。
+func (m *Map) Walk(key string, visit func(node string) (stop bool)) {
+	if len(m.keys) == 0 {
+		return
+	}
+
+	hash := int(m.hash([]byte(key)))
+	start := sort.Search(len(m.keys), func(i int) bool {
+		return m.keys[i] >= hash
+	})
+
+	seen := make(map[string]bool, len(m.hashMap))
+	for i := 0; i < len(m.keys); i++ {
+		node := m.hashMap[m.keys[(start+i)%len(m.keys)]]
+		if seen[node] {
+			continue
+		}
+		seen[node] = true
+		if visit(node) {
+			return
+		}
+	}
+}
@@ -0,0 +1,348 @@
+package rqcache
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math/rand"
+	"rqcache/lru"
+	pb "rqcache/rqcachepb"
+	"rqcache/singleflight"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Getter 用于加载某个 key 对应的源数据，由调用方实现。ctx 可以携带截止时间或
+// 取消信号，实现方应尽量尊重它（例如透传给数据库查询或下游 RPC）。
+type Getter interface {
+	Get(ctx context.Context, key string) ([]byte, error)
+}
+
+// GetterFunc 是一个函数适配器，使得普通函数也可以作为 Getter 使用
+type GetterFunc func(ctx context.Context, key string) ([]byte, error)
+
+// Get 实现 Getter 接口
+func (f GetterFunc) Get(ctx context.Context, key string) ([]byte, error) {
+	return f(ctx, key)
+}
+
+const (
+	// defaultHotCacheProbability 是远程获取到的值被复制进 hotCache 的默认概率分母，
+	// 即大约每 10 次远程命中才会有 1 次被写入 hotCache
+	defaultHotCacheProbability = 10
+
+	// defaultSweepInterval 是后台主动过期清理任务的默认采样间隔
+	defaultSweepInterval = time.Minute
+	// defaultSweepSampleSize 是后台主动过期清理任务每次默认抽取的 key 数量
+	defaultSweepSampleSize = 20
+)
+
+// CacheStats 是某一层缓存的累计命中/未命中次数快照
+type CacheStats struct {
+	Hits   int64
+	Misses int64
+}
+
+// Group 是一个缓存命名空间，关联着对应的数据加载方式（Getter）
+type Group struct {
+	name   string
+	getter Getter
+
+	mainCache cache // 本节点在一致性哈希环上拥有的 key
+	hotCache  cache // 非本节点拥有、但被频繁访问的远程 key 的副本
+
+	peers  PeerPicker
+	loader *singleflight.Group // 保证对同一个 key 的并发加载只会请求一次
+
+	hotCacheProbability int // 远程值被写入 hotCache 的概率为 1/hotCacheProbability
+
+	defaultTTL      time.Duration // 通过 Getter 加载的新值的默认过期时间，0 表示永不过期
+	sweepInterval   time.Duration // 后台主动过期清理任务的采样间隔，<=0 表示关闭
+	sweepSampleSize int           // 后台主动过期清理任务每次抽取的 key 数量
+
+	version int64 // 单调递增的写版本号，每次 Update/Remove 都会递增，见 nextVersion
+
+	mainHits, mainMisses int64
+	hotHits, hotMisses   int64
+}
+
+// GroupOption 用于在创建 Group 时配置可选参数
+type GroupOption func(*Group)
+
+// WithDefaultTTL 为该 Group 通过 Getter 新加载的值设置默认过期时间，
+// 0（默认值）表示永不过期
+func WithDefaultTTL(ttl time.Duration) GroupOption {
+	return func(g *Group) {
+		g.defaultTTL = ttl
+	}
+}
+
+// WithExpirySweepInterval 配置后台主动过期清理任务的采样间隔与每次抽取的
+// key 数量，interval <= 0 表示关闭后台清理，只依赖读取时的惰性过期检查
+func WithExpirySweepInterval(interval time.Duration, sampleSize int) GroupOption {
+	return func(g *Group) {
+		g.sweepInterval = interval
+		g.sweepSampleSize = sampleSize
+	}
+}
+
+var (
+	mu     sync.RWMutex
+	groups = make(map[string]*Group)
+)
+
+// NewGroup 创建一个 Group 实例，mainCache 与 hotCache 都使用默认的 LRU 淘汰策略。
+// 等价于 NewGroupWithPolicy(name, cacheBytes, getter, lru.NewLRUPolicy, opts...)。
+func NewGroup(name string, cacheBytes int64, getter Getter, opts ...GroupOption) *Group {
+	return NewGroupWithPolicy(name, cacheBytes, getter, lru.NewLRUPolicy, opts...)
+}
+
+// NewGroupWithPolicy 创建一个 Group 实例，并注册到全局表中供 GetGroup 查找。
+// cacheBytes 会按 7/8、1/8 的比例分别分配给 mainCache 与 hotCache，两者使用同一个
+// policyFactory 创建各自独立的淘汰策略实例。默认会为该 Group 启动一个后台
+// goroutine 定期采样清理过期条目，除非 opts 通过 WithExpirySweepInterval 关闭它。
+func NewGroupWithPolicy(name string, cacheBytes int64, getter Getter, policyFactory lru.PolicyFactory, opts ...GroupOption) *Group {
+	if getter == nil {
+		panic("nil Getter")
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	g := &Group{
+		name:                name,
+		getter:              getter,
+		mainCache:           cache{cacheBytes: cacheBytes * 7 / 8, newPolicy: policyFactory},
+		hotCache:            cache{cacheBytes: cacheBytes / 8, newPolicy: policyFactory},
+		loader:              &singleflight.Group{},
+		hotCacheProbability: defaultHotCacheProbability,
+		sweepInterval:       defaultSweepInterval,
+		sweepSampleSize:     defaultSweepSampleSize,
+	}
+	for _, opt := range opts {
+		opt(g)
+	}
+	groups[name] = g
+
+	if g.sweepInterval > 0 {
+		go g.sweepExpiredLoop()
+	}
+
+	return g
+}
+
+// GetGroup 返回之前用 NewGroup 创建的 Group，不存在时返回 nil
+func GetGroup(name string) *Group {
+	mu.RLock()
+	g := groups[name]
+	mu.RUnlock()
+	return g
+}
+
+// RegisterPeers 为 Group 注册一个 PeerPicker，用于挑选同伴节点，只能调用一次
+func (g *Group) RegisterPeers(peers PeerPicker) {
+	if g.peers != nil {
+		panic("RegisterPeers called more than once")
+	}
+	g.peers = peers
+}
+
+// SetHotCacheProbability 设置远程值被复制进 hotCache 的概率分母，
+// 例如传入 10 表示大约每 10 次远程命中复制 1 次，传入小于等于 1 的值表示每次都复制
+func (g *Group) SetHotCacheProbability(n int) {
+	if n <= 0 {
+		n = 1
+	}
+	g.hotCacheProbability = n
+}
+
+// sweepExpiredLoop 每隔 sweepInterval 对 mainCache 与 hotCache 各抽样
+// sweepSampleSize 个 key 做主动过期检查（Redis 式主动过期），使得没有被读取
+// 的过期条目也能及时释放内存，而不必等到容量淘汰
+func (g *Group) sweepExpiredLoop() {
+	ticker := time.NewTicker(g.sweepInterval)
+	defer ticker.Stop()
+	for range ticker.C {
+		g.mainCache.sweepExpired(g.sweepSampleSize)
+		g.hotCache.sweepExpired(g.sweepSampleSize)
+	}
+}
+
+// Get 查找 key 对应的缓存值：依次尝试本地 mainCache、本地 hotCache，
+// 都未命中时再通过 load 从同伴节点或数据源加载。ctx 的截止时间会被透传给
+// 同伴节点的 RPC 以及用户提供的 Getter。
+func (g *Group) Get(ctx context.Context, key string) (ByteView, error) {
+	value, _, _, err := g.getWithTTL(ctx, key)
+	return value, err
+}
+
+// GetWithTTL 和 Get 行为一致，但额外返回该值的剩余存活时间（0 表示永不过期）
+// 以及写入版本号。HTTPPool/GRPCPool 在向同伴节点返回数据时用它们来填充
+// pb.Response 的 TtlSeconds 与 Version 字段。
+func (g *Group) GetWithTTL(ctx context.Context, key string) (value ByteView, ttl time.Duration, version int64, err error) {
+	return g.getWithTTL(ctx, key)
+}
+
+func (g *Group) getWithTTL(ctx context.Context, key string) (value ByteView, ttl time.Duration, version int64, err error) {
+	if key == "" {
+		return ByteView{}, 0, 0, fmt.Errorf("key is required")
+	}
+
+	if v, expiresAt, ver, ok := g.mainCache.getWithExpiry(key); ok {
+		atomic.AddInt64(&g.mainHits, 1)
+		log.Println("[RQCache] hit (main)")
+		return v, remainingTTL(expiresAt), ver, nil
+	}
+	atomic.AddInt64(&g.mainMisses, 1)
+
+	if v, expiresAt, ver, ok := g.hotCache.getWithExpiry(key); ok {
+		atomic.AddInt64(&g.hotHits, 1)
+		log.Println("[RQCache] hit (hot)")
+		return v, remainingTTL(expiresAt), ver, nil
+	}
+	atomic.AddInt64(&g.hotMisses, 1)
+
+	return g.load(ctx, key)
+}
+
+// loadResult 携带 load 过程中同时产出的值、其剩余存活时间与写入版本号，
+// 用于在 singleflight.Group.Do 的 interface{} 返回值中一起传递
+type loadResult struct {
+	value   ByteView
+	ttl     time.Duration
+	version int64
+}
+
+// load 在本地两级缓存都未命中时调用：优先尝试从拥有该 key 的同伴节点获取，
+// 没有同伴节点或获取失败时回退到本地数据源
+func (g *Group) load(ctx context.Context, key string) (value ByteView, ttl time.Duration, version int64, err error) {
+	viewi, err := g.loader.Do(key, func() (interface{}, error) {
+		if g.peers != nil {
+			if peer, ok := g.peers.PickPeer(key); ok {
+				if v, t, ver, peerErr := g.getFromPeer(ctx, peer, key); peerErr == nil {
+					return loadResult{value: v, ttl: t, version: ver}, nil
+				} else {
+					log.Println("[RQCache] Failed to get from peer", peerErr)
+				}
+			}
+		}
+
+		v, t, ver, localErr := g.getLocally(ctx, key)
+		return loadResult{value: v, ttl: t, version: ver}, localErr
+	})
+	if err == nil {
+		r := viewi.(loadResult)
+		return r.value, r.ttl, r.version, nil
+	}
+	return
+}
+
+// getLocally 调用用户提供的 Getter 从数据源加载 key，以 Group 的默认 TTL
+// 写入 mainCache，代表本节点是该 key 在一致性哈希环上的所有者。写入时打上
+// 的版本号是调用 Getter 之前读到的 Group 写版本计数器（由 Update/Remove 推进），
+// 而不是新分配一个版本号：加载期间若有一次并发的 Update 写入了更高版本的值，
+// cache.add 会跳过这次较旧的加载结果。
+func (g *Group) getLocally(ctx context.Context, key string) (ByteView, time.Duration, int64, error) {
+	version := atomic.LoadInt64(&g.version)
+	bytes, err := g.getter.Get(ctx, key)
+	if err != nil {
+		return ByteView{}, 0, 0, err
+	}
+	value := ByteView{b: cloneBytes(bytes)}
+	g.mainCache.add(key, value, g.defaultTTL, version)
+	return value, g.defaultTTL, version, nil
+}
+
+// getFromPeer 通过 PeerGetter 从同伴节点获取 key 对应的值、剩余 TTL 与版本号，
+// 并以 hotCacheProbability 的概率将其写入 hotCache
+func (g *Group) getFromPeer(ctx context.Context, peer PeerGetter, key string) (ByteView, time.Duration, int64, error) {
+	req := &pb.Request{
+		Group: g.name,
+		Key:   key,
+	}
+	res := &pb.Response{}
+	err := peer.Get(ctx, req, res)
+	if err != nil {
+		return ByteView{}, 0, 0, err
+	}
+	value := ByteView{b: res.Value}
+	ttl := time.Duration(res.TtlSeconds) * time.Second
+
+	if rand.Intn(g.hotCacheProbability) == 0 {
+		g.hotCache.add(key, value, ttl, res.Version)
+	}
+
+	return value, ttl, res.Version, nil
+}
+
+// nextVersion 分配下一个写版本号，每次 Update/Remove 调用都会推进它
+func (g *Group) nextVersion() int64 {
+	return atomic.AddInt64(&g.version, 1)
+}
+
+// isOwner 判断本节点是否是 key 在一致性哈希环上的所有者：没有注册 PeerPicker，
+// 或 PickPeer 认为该 key 不属于任何同伴节点时，本节点就是所有者
+func (g *Group) isOwner(key string) bool {
+	if g.peers == nil {
+		return true
+	}
+	_, ok := g.peers.PickPeer(key)
+	return !ok
+}
+
+// Update 用新值覆盖 key：本节点是该 key 的 owner 时写入 mainCache，否则写入
+// hotCache（视作一份之后会被写失效通知刷新的本地副本）。随后向所有已知的
+// 同伴节点异步广播一次写失效通知，版本号不晚于本次更新的缓存副本会被对方
+// 丢弃。这是一个 opt-in 的写操作：groupcache 原本假设数据源只读、各节点的
+// 缓存天然一致，调用 Update/Remove 意味着主动放弃这部分简单性，换取数据
+// 可变场景下的跨节点一致性。
+func (g *Group) Update(ctx context.Context, key string, value ByteView) {
+	version := g.nextVersion()
+	if g.isOwner(key) {
+		g.mainCache.add(key, value, g.defaultTTL, version)
+	} else {
+		g.hotCache.add(key, value, g.defaultTTL, version)
+	}
+	g.invalidatePeers(ctx, key, version)
+}
+
+// Remove 从本地两级缓存中移除 key，并向所有已知的同伴节点异步广播一次写
+// 失效通知，使它们各自缓存的副本也被清除。同样是 opt-in 的写操作，语义与
+// Update 相同，参见 Update 的文档。
+func (g *Group) Remove(ctx context.Context, key string) {
+	version := g.nextVersion()
+	g.mainCache.remove(key)
+	g.hotCache.remove(key)
+	g.invalidatePeers(ctx, key, version)
+}
+
+// applyInvalidation 根据来自同伴节点的写失效通知，丢弃本地两级缓存中版本
+// 不晚于 version 的 key 副本；本地恰好持有更新版本副本时不受影响
+func (g *Group) applyInvalidation(key string, version int64) {
+	g.mainCache.invalidate(key, version)
+	g.hotCache.invalidate(key, version)
+}
+
+// invalidatePeers 在 g.peers 实现了 PeerInvalidator 时，向它广播的全部同伴
+// 节点发送一次写失效通知；g.peers 为 nil 或只实现了只读的 PeerPicker 时
+// 直接跳过，此时 Update/Remove 仍然对本地缓存生效，只是不具备跨节点一致性
+func (g *Group) invalidatePeers(ctx context.Context, key string, version int64) {
+	if invalidator, ok := g.peers.(PeerInvalidator); ok {
+		invalidator.InvalidatePeers(ctx, g.name, key, version)
+	}
+}
+
+// MainCacheStats 返回 mainCache 的累计命中/未命中次数
+func (g *Group) MainCacheStats() CacheStats {
+	return CacheStats{
+		Hits:   atomic.LoadInt64(&g.mainHits),
+		Misses: atomic.LoadInt64(&g.mainMisses),
+	}
+}
+
+// HotCacheStats 返回 hotCache 的累计命中/未命中次数
+func (g *Group) HotCacheStats() CacheStats {
+	return CacheStats{
+		Hits:   atomic.LoadInt64(&g.hotHits),
+		Misses: atomic.LoadInt64(&g.hotMisses),
+	}
+}
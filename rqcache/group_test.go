@@ -0,0 +1,70 @@
+package rqcache
+
+import (
+	"context"
+	"fmt"
+	"testing"
+)
+
+// stubPeerPicker 是一个最小的 PeerPicker 实现，只用于在测试中控制
+// PickPeer 的返回值，不实现 PeerInvalidator。
+type stubPeerPicker struct {
+	pick func(key string) (PeerGetter, bool)
+}
+
+func (s stubPeerPicker) PickPeer(key string) (PeerGetter, bool) {
+	return s.pick(key)
+}
+
+// TestGroupUpdateOwnedKeyWritesMainCache 是 chunk0-7 的回归测试：当本节点是
+// key 的一致性哈希 owner 时（PickPeer 返回 ok=false），Update 必须写入
+// mainCache 而不是 hotCache。
+func TestGroupUpdateOwnedKeyWritesMainCache(t *testing.T) {
+	g := NewGroup("test-update-owned-key", 1<<20, GetterFunc(func(ctx context.Context, key string) ([]byte, error) {
+		return nil, fmt.Errorf("不应该被调用：%s", key)
+	}))
+	g.RegisterPeers(stubPeerPicker{pick: func(string) (PeerGetter, bool) { return nil, false }})
+
+	g.Update(context.Background(), "key1", ByteView{b: []byte("v1")})
+
+	if _, _, _, ok := g.mainCache.getWithExpiry("key1"); !ok {
+		t.Fatalf("expected key1 to be written to mainCache for a self-owned key")
+	}
+	if _, _, _, ok := g.hotCache.getWithExpiry("key1"); ok {
+		t.Fatalf("key1 should not have been written to hotCache for a self-owned key")
+	}
+}
+
+// TestGroupUpdateRemoteKeyWritesHotCache 验证反过来的情况：PickPeer 认为
+// key 属于某个远程节点时，Update 只应该把它当成本地副本写入 hotCache。
+func TestGroupUpdateRemoteKeyWritesHotCache(t *testing.T) {
+	g := NewGroup("test-update-remote-key", 1<<20, GetterFunc(func(ctx context.Context, key string) ([]byte, error) {
+		return nil, fmt.Errorf("不应该被调用：%s", key)
+	}))
+	g.RegisterPeers(stubPeerPicker{pick: func(string) (PeerGetter, bool) { return nil, true }})
+
+	g.Update(context.Background(), "key1", ByteView{b: []byte("v1")})
+
+	if _, _, _, ok := g.mainCache.getWithExpiry("key1"); ok {
+		t.Fatalf("key1 should not have been written to mainCache for a remote-owned key")
+	}
+	if _, _, _, ok := g.hotCache.getWithExpiry("key1"); !ok {
+		t.Fatalf("expected key1 to be written to hotCache for a remote-owned key")
+	}
+}
+
+// TestGroupRemoveOwnedKey 验证 Remove 会清掉 mainCache 中 owner 持有的条目
+func TestGroupRemoveOwnedKey(t *testing.T) {
+	g := NewGroup("test-remove-owned-key", 1<<20, GetterFunc(func(ctx context.Context, key string) ([]byte, error) {
+		return []byte("v1"), nil
+	}))
+
+	if _, err := g.Get(context.Background(), "key1"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	g.Remove(context.Background(), "key1")
+
+	if _, _, _, ok := g.mainCache.getWithExpiry("key1"); ok {
+		t.Fatalf("expected key1 to be removed from mainCache")
+	}
+}
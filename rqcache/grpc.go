@@ -0,0 +1,247 @@
+package rqcache
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"rqcache/consistenthash"
+	pb "rqcache/rqcachepb"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+)
+
+// defaultGRPCReplicas 是一致性哈希环上每个 gRPC 同伴节点的默认虚拟节点数量。
+// defaultDrainGrace 是节点被移出 Set 之后，其 grpcGetter 连接仍保留多久才
+// 真正关闭，用来给已经选中该节点的在途请求留出完成时间。
+const (
+	defaultGRPCReplicas = 50
+	defaultDrainGrace   = 30 * time.Second
+)
+
+// GRPCPool 是 HTTPPool 的 gRPC 版本：同伴节点之间复用基于 HTTP/2 的持久
+// grpc.ClientConn，避免 HTTPPool 每次 Get 都要重新进行一次 TCP+HTTP/1.1 握手。
+// 两者都实现了 PeerPicker，使用哪一种由调用方在 Group.RegisterPeers 时选择。
+type GRPCPool struct {
+	self string // 当前节点的地址，例如 "10.0.0.2:8008"
+
+	mu         sync.Mutex
+	peers      *consistenthash.Map
+	getters    map[string]*grpcGetter
+	drainGrace time.Duration
+}
+
+// NewGRPCPool 初始化一个 gRPC 同伴节点池
+func NewGRPCPool(self string) *GRPCPool {
+	return &GRPCPool{self: self}
+}
+
+// Log 使用服务器名称记录信息
+func (p *GRPCPool) Log(format string, v ...interface{}) {
+	log.Printf("[gRPC 服务器 %s] %s", p.self, fmt.Sprintf(format, v...))
+}
+
+// SetDrainGrace 设置节点被移出 Set 之后、其连接被真正关闭之前的排空等待时间
+func (p *GRPCPool) SetDrainGrace(d time.Duration) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.drainGrace = d
+}
+
+func (p *GRPCPool) drainGraceLocked() time.Duration {
+	if p.drainGrace <= 0 {
+		return defaultDrainGrace
+	}
+	return p.drainGrace
+}
+
+// UseRegistry 订阅 reg 推送的成员列表，并在每次收到更新时调用 Set 重建节点
+// 池，直到 ctx 被取消为止，使成员关系由注册中心的实际存活状态驱动。
+func (p *GRPCPool) UseRegistry(ctx context.Context, reg PeerRegistry) {
+	usePeerRegistry(ctx, reg, func(peers []string) { p.Set(peers...) })
+}
+
+// Set 更新节点池的同伴列表。对于更新前后都存在的节点，已建立的 grpc.ClientConn
+// 会被保留复用；被移除的节点不会立即断开连接，而是延迟 drainGraceLocked()
+// 之后再关闭，让已经持有该节点 grpcGetter 的在途请求有机会正常完成。
+func (p *GRPCPool) Set(peers ...string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	next := make(map[string]*grpcGetter, len(peers))
+	for _, peer := range peers {
+		if g, ok := p.getters[peer]; ok {
+			next[peer] = g
+			continue
+		}
+		next[peer] = newGRPCGetter(peer)
+	}
+
+	var removed []*grpcGetter
+	for peer, g := range p.getters {
+		if _, ok := next[peer]; !ok {
+			removed = append(removed, g)
+		}
+	}
+
+	p.peers = consistenthash.New(defaultGRPCReplicas, nil)
+	p.peers.Add(peers...)
+	p.getters = next
+
+	grace := p.drainGraceLocked()
+	for _, g := range removed {
+		g := g
+		time.AfterFunc(grace, func() { g.close() })
+	}
+}
+
+// PickPeer 根据键选择一个同伴节点
+func (p *GRPCPool) PickPeer(key string) (PeerGetter, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if peer := p.peers.Get(key); peer != "" && peer != p.self {
+		p.Log("选择同伴节点 %s", peer)
+		return p.getters[peer], true
+	}
+	return nil, false
+}
+
+// Close 关闭该 Pool 持有的所有 gRPC 连接
+func (p *GRPCPool) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	var firstErr error
+	for _, g := range p.getters {
+		if err := g.close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+var _ PeerPicker = (*GRPCPool)(nil)
+
+// grpcGetter 实现了 PeerGetter 接口，通过一个惰性建立并复用的 grpc.ClientConn
+// 调用同伴节点的 Cache 服务
+type grpcGetter struct {
+	addr string
+
+	mu   sync.Mutex
+	conn *grpc.ClientConn
+}
+
+func newGRPCGetter(addr string) *grpcGetter {
+	return &grpcGetter{addr: addr}
+}
+
+// client 惰性建立（并复用）到该同伴节点的 gRPC 连接。底层是一个基于 HTTP/2
+// 的持久连接，可以被多个并发请求复用而不需要每次都重新握手。
+func (g *grpcGetter) client() (pb.CacheClient, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.conn == nil {
+		conn, err := grpc.Dial(g.addr, grpc.WithInsecure())
+		if err != nil {
+			return nil, err
+		}
+		g.conn = conn
+	}
+	return pb.NewCacheClient(g.conn), nil
+}
+
+// Get 通过 gRPC 获取 key 对应的值，ctx 的截止时间由 grpc 客户端自动透传给服务端
+func (g *grpcGetter) Get(ctx context.Context, in *pb.Request, out *pb.Response) error {
+	client, err := g.client()
+	if err != nil {
+		return err
+	}
+	res, err := client.Get(ctx, in)
+	if err != nil {
+		return err
+	}
+	*out = *res
+	return nil
+}
+
+// BatchGet 通过一次 BatchGet 流式 RPC 批量获取多个 key，把多次请求合并成一次
+// 往返；返回结果按服务端发送的顺序排列（即请求 keys 的顺序）。
+func (g *grpcGetter) BatchGet(ctx context.Context, group string, keys []string) ([]*pb.Response, error) {
+	client, err := g.client()
+	if err != nil {
+		return nil, err
+	}
+	stream, err := client.BatchGet(ctx, &pb.BatchRequest{Group: group, Keys: keys})
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]*pb.Response, 0, len(keys))
+	for {
+		res, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, res)
+	}
+	return results, nil
+}
+
+func (g *grpcGetter) close() error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.conn == nil {
+		return nil
+	}
+	err := g.conn.Close()
+	g.conn = nil
+	return err
+}
+
+var _ PeerGetter = (*grpcGetter)(nil)
+
+// cacheGRPCServer 实现 pb.CacheServer，把收到的 gRPC 请求路由到本地对应的 Group
+type cacheGRPCServer struct {
+	pb.UnimplementedCacheServer
+}
+
+// NewCacheGRPCServer 创建一个可以注册到 grpc.Server 的 pb.CacheServer 实现，
+// 用法类似 HTTPPool：grpcServer := grpc.NewServer(); pb.RegisterCacheServer(grpcServer, rqcache.NewCacheGRPCServer())
+func NewCacheGRPCServer() pb.CacheServer {
+	return &cacheGRPCServer{}
+}
+
+func (s *cacheGRPCServer) Get(ctx context.Context, in *pb.Request) (*pb.Response, error) {
+	group := GetGroup(in.GetGroup())
+	if group == nil {
+		return nil, fmt.Errorf("没有该组：%s", in.GetGroup())
+	}
+	view, ttl, version, err := group.GetWithTTL(ctx, in.GetKey())
+	if err != nil {
+		return nil, err
+	}
+	return &pb.Response{Value: view.ByteSlice(), TtlSeconds: int64((ttl + time.Second - 1) / time.Second), Version: version}, nil
+}
+
+func (s *cacheGRPCServer) BatchGet(req *pb.BatchRequest, stream pb.Cache_BatchGetServer) error {
+	group := GetGroup(req.GetGroup())
+	if group == nil {
+		return fmt.Errorf("没有该组：%s", req.GetGroup())
+	}
+	for _, key := range req.GetKeys() {
+		view, ttl, version, err := group.GetWithTTL(stream.Context(), key)
+		if err != nil {
+			return err
+		}
+		if err := stream.Send(&pb.Response{Value: view.ByteSlice(), TtlSeconds: int64((ttl + time.Second - 1) / time.Second), Version: version}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+var _ pb.CacheServer = (*cacheGRPCServer)(nil)
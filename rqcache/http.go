@@ -1,6 +1,7 @@
 package rqcache
 
 import (
+	"context"
 	"fmt"
 	"io/ioutil"
 	"log"
@@ -8,25 +9,31 @@ import (
 	"net/url"
 	"rqcache/consistenthash"
 	pb "rqcache/rqcachepb"
+	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/golang/protobuf/proto"
 )
 
-// 默认的基本路径和副本数量
+// 默认的基本路径、副本数量和有界负载的 ε
 const (
-	defaultBasePath = "/_rqcache/"
-	defaultReplicas = 50
+	defaultBasePath           = "/_rqcache/"
+	defaultReplicas           = 50
+	defaultBoundedLoadEpsilon = 0.25 // 允许单个节点负载超过平均值的比例上限
 )
 
 // HTTPPool 实现了 PeerPicker 接口，用于管理一组 HTTP 同伴节点。
 type HTTPPool struct {
 	self        string                 // 当前节点的基本 URL，例如 "https://example.net:8000"
 	basePath    string                 // HTTP 路由的基本路径
-	mu          sync.Mutex             // 保护 peers 和 httpGetters 的并发访问
+	mu          sync.Mutex             // 保护 peers、httpGetters、load 的并发访问
 	peers       *consistenthash.Map    // 一致性哈希算法的实例，用于选择节点
 	httpGetters map[string]*httpGetter // 同伴节点的 HTTP 客户端，按格式 "http://10.0.0.2:8008" 进行索引
+	load        map[string]*int64      // 每个同伴节点当前的 in-flight 请求数，供有界负载策略使用
+	loadEpsilon float64                // 有界负载的 ε，<=0 时回退到 defaultBoundedLoadEpsilon
 }
 
 // NewHTTPPool 初始化一个 HTTP 同伴节点池。
@@ -37,6 +44,15 @@ func NewHTTPPool(self string) *HTTPPool {
 	}
 }
 
+// SetLoadEpsilon 设置有界负载策略中的 ε：PickPeer 会跳过当前 in-flight
+// 请求数超过 (1+ε)*平均负载 的节点，ε 越小对负载越敏感，但过小会导致热点
+// key 在节点间频繁漂移
+func (p *HTTPPool) SetLoadEpsilon(epsilon float64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.loadEpsilon = epsilon
+}
+
 // Log 使用服务器名称记录信息
 func (p *HTTPPool) Log(format string, v ...interface{}) {
 	log.Printf("[服务器 %s] %s", p.self, fmt.Sprintf(format, v...))
@@ -64,14 +80,19 @@ func (p *HTTPPool) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	view, err := group.Get(key)
+	if r.Method == http.MethodDelete {
+		p.serveInvalidate(w, r, group, key)
+		return
+	}
+
+	view, ttl, version, err := group.GetWithTTL(r.Context(), key)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	// 将值以 proto 消息的形式写入响应体
-	body, err := proto.Marshal(&pb.Response{Value: view.ByteSlice()})
+	// 将值以 proto 消息的形式写入响应体，同时带上剩余 TTL 与版本号
+	body, err := proto.Marshal(&pb.Response{Value: view.ByteSlice(), TtlSeconds: int64((ttl + time.Second - 1) / time.Second), Version: version})
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -81,46 +102,190 @@ func (p *HTTPPool) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	w.Write(body)
 }
 
-// Set 更新节点池的同伴列表
+// serveInvalidate 处理 DELETE /_rqcache/<group>/<key>?version=<version>：这是
+// Group.Update/Group.Remove 向同伴节点广播写失效通知使用的路由，非 owner
+// 节点收到后丢弃本地版本不晚于 version 的缓存副本
+func (p *HTTPPool) serveInvalidate(w http.ResponseWriter, r *http.Request, group *Group, key string) {
+	version, err := strconv.ParseInt(r.URL.Query().Get("version"), 10, 64)
+	if err != nil {
+		http.Error(w, "缺少或非法的 version 参数", http.StatusBadRequest)
+		return
+	}
+	group.applyInvalidation(key, version)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// UseRegistry 订阅 reg 推送的成员列表，并在每次收到更新时以权重 1 调用 Set
+// 重建节点池，直到 ctx 被取消为止。这让节点加入/离开由注册中心的实际存活
+// 状态（etcd 租约或 gossip 心跳）驱动，调用方不再需要在每次成员变化时手工
+// 调用 Set。由于 httpGetter 不持有长连接，已经拿到某个 httpGetter 的请求
+// 不受节点被移出 Set 的影响，可以自然地跑完，无需额外的排空逻辑。
+func (p *HTTPPool) UseRegistry(ctx context.Context, reg PeerRegistry) {
+	usePeerRegistry(ctx, reg, func(peers []string) { p.Set(peers...) })
+}
+
+// Set 更新节点池的同伴列表，所有节点使用相同的权重 1
 func (p *HTTPPool) Set(peers ...string) {
+	weights := make(map[string]int, len(peers))
+	for _, peer := range peers {
+		weights[peer] = 1
+	}
+	p.SetWeighted(weights)
+}
+
+// SetWeighted 更新节点池的同伴列表，weights 中每个节点的权重决定了它在一致性
+// 哈希环上分担的虚拟节点数量，适合节点之间硬件规格不一致的场景。对于更新
+// 前后都存在的节点，沿用原有的 load 计数器而不是归零，因为 UseRegistry 驱动
+// 的成员更新（etcd 续约、gossip 收敛）可能很频繁，不应每次都抹掉有界负载
+// 策略依赖的 in-flight 信号。
+func (p *HTTPPool) SetWeighted(weights map[string]int) {
 	p.mu.Lock()
 	defer p.mu.Unlock()
 	p.peers = consistenthash.New(defaultReplicas, nil)
-	p.peers.Add(peers...)
-	p.httpGetters = make(map[string]*httpGetter, len(peers))
-	for _, peer := range peers {
-		p.httpGetters[peer] = &httpGetter{baseURL: peer + p.basePath}
+	httpGetters := make(map[string]*httpGetter, len(weights))
+	load := make(map[string]*int64, len(weights))
+	for peer, weight := range weights {
+		p.peers.AddWeighted(peer, weight)
+		counter, ok := p.load[peer]
+		if !ok {
+			counter = new(int64)
+		}
+		load[peer] = counter
+		httpGetters[peer] = &httpGetter{baseURL: peer + p.basePath, load: counter}
+	}
+	p.httpGetters = httpGetters
+	p.load = load
+}
+
+// avgLoadLocked 返回所有同伴节点当前 in-flight 请求数的平均值，调用方必须持有 p.mu
+func (p *HTTPPool) avgLoadLocked() float64 {
+	if len(p.load) == 0 {
+		return 0
+	}
+	var total int64
+	for _, counter := range p.load {
+		total += atomic.LoadInt64(counter)
+	}
+	return float64(total) / float64(len(p.load))
+}
+
+// epsilonLocked 返回有界负载策略的 ε，调用方必须持有 p.mu
+func (p *HTTPPool) epsilonLocked() float64 {
+	if p.loadEpsilon <= 0 {
+		return defaultBoundedLoadEpsilon
 	}
+	return p.loadEpsilon
 }
 
-// PickPeer 根据键选择一个同伴节点
+// PickPeer 根据键选择一个同伴节点。先用普通一致性哈希确定真正的 ring owner：
+// 如果 owner 就是本节点，必须返回 ok=false，让调用方走本地数据源，而不是
+// 把有界负载的跳过逻辑用到自己身上、把请求又转发给别的节点（那样会在本节点
+// 和任何转发目标之间来回跳，永远到不了真正拥有数据的本节点）。只有当 owner
+// 是别的节点时，才沿环顺时针应用「有界负载」策略：跳过当前 in-flight 请求
+// 数超过 (1+ε)*平均负载 的候选节点，都过载时退化为普通一致性哈希选出的 owner。
 func (p *HTTPPool) PickPeer(key string) (PeerGetter, bool) {
 	p.mu.Lock()
 	defer p.mu.Unlock()
-	if peer := p.peers.Get(key); peer != "" && peer != p.self {
-		p.Log("选择同伴节点 %s", peer)
-		return p.httpGetters[peer], true
+	if p.peers == nil {
+		return nil, false
+	}
+
+	owner := p.peers.Get(key)
+	if owner == "" || owner == p.self {
+		return nil, false
+	}
+
+	maxLoad := int64((1 + p.epsilonLocked()) * p.avgLoadLocked())
+	var picked string
+	p.peers.Walk(key, func(node string) bool {
+		if node == p.self {
+			return false
+		}
+		if counter, ok := p.load[node]; ok && atomic.LoadInt64(counter) > maxLoad {
+			return false
+		}
+		picked = node
+		return true
+	})
+
+	if picked == "" {
+		picked = owner
+	}
+
+	p.Log("选择同伴节点 %s", picked)
+	return p.httpGetters[picked], true
+}
+
+// ServeMetrics 以 Prometheus 文本格式暴露每个同伴节点当前的 in-flight 请求数，
+// 可以挂载到单独的路径（例如 "/_rqcache/metrics"）供抓取
+func (p *HTTPPool) ServeMetrics(w http.ResponseWriter, r *http.Request) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	fmt.Fprintln(w, "# HELP rqcache_peer_inflight_requests 当前发往该同伴节点且尚未返回的请求数")
+	fmt.Fprintln(w, "# TYPE rqcache_peer_inflight_requests gauge")
+	for peer, counter := range p.load {
+		fmt.Fprintf(w, "rqcache_peer_inflight_requests{peer=%q} %d\n", peer, atomic.LoadInt64(counter))
+	}
+}
+
+// InvalidatePeers 实现 PeerInvalidator：向当前已知的全部同伴节点（不含自身）
+// 各发起一次 DELETE /_rqcache/<group>/<key>?version=<version> 请求，要求对方
+// 丢弃版本不晚于 version 的本地副本。逐个节点异步发出、互不等待，单个同伴
+// 节点暂时不可达只会记一条日志，不应拖慢或阻塞调用方的 Update/Remove。
+func (p *HTTPPool) InvalidatePeers(ctx context.Context, group, key string, version int64) {
+	p.mu.Lock()
+	getters := make([]*httpGetter, 0, len(p.httpGetters))
+	for peer, getter := range p.httpGetters {
+		if peer == p.self {
+			continue
+		}
+		getters = append(getters, getter)
+	}
+	p.mu.Unlock()
+
+	for _, getter := range getters {
+		getter := getter
+		go func() {
+			if err := getter.invalidate(ctx, group, key, version); err != nil {
+				p.Log("写失效通知发送失败：%v", err)
+			}
+		}()
 	}
-	return nil, false
 }
 
 // PeerPicker 接口的实现，表示可以选择一个同伴节点
 var _ PeerPicker = (*HTTPPool)(nil)
 
+// PeerInvalidator 接口的实现，表示可以向全部同伴节点广播写失效通知
+var _ PeerInvalidator = (*HTTPPool)(nil)
+
 // httpGetter 实现了 PeerGetter 接口，表示通过 HTTP 获取值的客户端
 type httpGetter struct {
 	baseURL string
+	load    *int64 // 指向 HTTPPool.load 中该节点的计数器，与 PickPeer 共享
 }
 
-// Get 通过 HTTP 获取值的实现
-func (h *httpGetter) Get(in *pb.Request, out *pb.Response) error {
+// Get 通过 HTTP 获取值的实现，ctx 的截止时间会被应用到底层的 HTTP 请求上。
+// 请求期间会对 load 计数器加一，返回前减一，供有界负载策略观测。
+func (h *httpGetter) Get(ctx context.Context, in *pb.Request, out *pb.Response) error {
+	if h.load != nil {
+		atomic.AddInt64(h.load, 1)
+		defer atomic.AddInt64(h.load, -1)
+	}
+
 	u := fmt.Sprintf(
 		"%v%v/%v",
 		h.baseURL,
 		url.QueryEscape(in.GetGroup()),
 		url.QueryEscape(in.GetKey()),
 	)
-	res, err := http.Get(u)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return err
+	}
+	res, err := http.DefaultClient.Do(req)
 	if err != nil {
 		return err
 	}
@@ -142,5 +307,31 @@ func (h *httpGetter) Get(in *pb.Request, out *pb.Response) error {
 	return nil
 }
 
+// invalidate 向该同伴节点发送一次写失效通知（DELETE /_rqcache/<group>/<key>），
+// version 是触发本次失效的写操作版本号
+func (h *httpGetter) invalidate(ctx context.Context, group, key string, version int64) error {
+	u := fmt.Sprintf(
+		"%v%v/%v?version=%d",
+		h.baseURL,
+		url.QueryEscape(group),
+		url.QueryEscape(key),
+		version,
+	)
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, u, nil)
+	if err != nil {
+		return err
+	}
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK && res.StatusCode != http.StatusNoContent {
+		return fmt.Errorf("服务器返回: %v", res.Status)
+	}
+	return nil
+}
+
 // PeerGetter 接口的实现，表示可以通过 HTTP 获取值
 var _ PeerGetter = (*httpGetter)(nil)
@@ -0,0 +1,88 @@
+package lru
+
+// countMinSketch 是一个 4 bit 计数的 Count-Min Sketch，用作 TinyLFUPolicy 的
+// 准入过滤器：只需要近似的访问频次用于相对比较，不需要精确计数，因此用
+// 4 bit（两个计数共享一个 byte）换取远小于精确计数表的内存占用。计数达到
+// resetAfter 次增长后整体减半（老化），避免频次被很久以前的访问模式主导，
+// 从而让准入判断能跟上访问模式的变化。
+type countMinSketch struct {
+	rows       [4][]uint8 // 4 个哈希函数各自的计数行，每个槽位占 4 bit
+	width      int
+	additions  int
+	resetAfter int
+}
+
+// cmsMaxCount 是 4 bit 计数器能表示的最大值
+const cmsMaxCount = 15
+
+// newCountMinSketch 创建一个宽度为 width 的 Count-Min Sketch，width 通常取
+// 容量除以单条目预估大小，resetAfter 控制多少次 add 之后触发一次老化
+func newCountMinSketch(width, resetAfter int) *countMinSketch {
+	if width <= 0 {
+		width = 1
+	}
+	cms := &countMinSketch{width: width, resetAfter: resetAfter}
+	for i := range cms.rows {
+		cms.rows[i] = make([]uint8, (width+1)/2)
+	}
+	return cms
+}
+
+// hash 是一个简单的 FNV 变体，按 seed 区分 4 个独立的哈希函数
+func (cms *countMinSketch) hash(key string, seed int) uint32 {
+	h := uint32(2166136261) ^ uint32(seed*0x9e3779b1)
+	for i := 0; i < len(key); i++ {
+		h ^= uint32(key[i])
+		h *= 16777619
+	}
+	return h
+}
+
+// slot 返回 key 在某一行中对应的 byte 下标以及在该 byte 内的位移
+func (cms *countMinSketch) slot(row int, key string) (idx int, shift uint) {
+	pos := int(cms.hash(key, row)) % cms.width
+	if pos < 0 {
+		pos += cms.width
+	}
+	return pos / 2, uint(pos%2) * 4
+}
+
+// get 返回 key 在 4 行中的最小计数，作为对其访问频次的估计
+func (cms *countMinSketch) get(key string) uint8 {
+	min := uint8(cmsMaxCount)
+	for row := range cms.rows {
+		idx, shift := cms.slot(row, key)
+		v := (cms.rows[row][idx] >> shift) & 0x0F
+		if v < min {
+			min = v
+		}
+	}
+	return min
+}
+
+// add 记录一次 key 的访问，达到 resetAfter 次 add 后触发老化
+func (cms *countMinSketch) add(key string) {
+	for row := range cms.rows {
+		idx, shift := cms.slot(row, key)
+		v := (cms.rows[row][idx] >> shift) & 0x0F
+		if v < cmsMaxCount {
+			cms.rows[row][idx] += 1 << shift
+		}
+	}
+	cms.additions++
+	if cms.resetAfter > 0 && cms.additions >= cms.resetAfter {
+		cms.age()
+	}
+}
+
+// age 将所有计数减半，是 TinyLFU 的"老化"步骤
+func (cms *countMinSketch) age() {
+	for row := range cms.rows {
+		for i := range cms.rows[row] {
+			lo := (cms.rows[row][i] & 0x0F) / 2
+			hi := ((cms.rows[row][i] >> 4) & 0x0F) / 2
+			cms.rows[row][i] = lo | (hi << 4)
+		}
+	}
+	cms.additions = 0
+}
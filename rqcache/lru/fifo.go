@@ -0,0 +1,111 @@
+package lru
+
+import "container/list"
+
+// fifoEntry 是双向链表节点所存储的对象
+type fifoEntry struct {
+	key   string
+	value Value
+}
+
+// FIFOPolicy 实现了先进先出（FIFO）淘汰策略：完全不考虑访问情况，只按写入
+// 顺序淘汰最早写入的条目，实现和开销都是几种策略中最小的
+type FIFOPolicy struct {
+	maxBytes  int64
+	nbytes    int64
+	ll        *list.List
+	cache     map[string]*list.Element
+	OnEvicted func(key string, value Value)
+}
+
+// NewFIFOPolicy 创建一个 FIFOPolicy，maxBytes 为 0 表示不限制容量
+func NewFIFOPolicy(maxBytes int64, onEvicted func(key string, value Value)) Policy {
+	return &FIFOPolicy{
+		maxBytes:  maxBytes,
+		ll:        list.New(),
+		cache:     make(map[string]*list.Element),
+		OnEvicted: onEvicted,
+	}
+}
+
+// Add 写入一个键值对；已存在的 key 只更新值，不改变其写入顺序
+func (c *FIFOPolicy) Add(key string, value Value) {
+	if ele, ok := c.cache[key]; ok {
+		kv := ele.Value.(*fifoEntry)
+		c.nbytes += int64(value.Len()) - int64(kv.value.Len())
+		kv.value = value
+	} else {
+		ele := c.ll.PushFront(&fifoEntry{key, value})
+		c.cache[key] = ele
+		c.nbytes += int64(len(key)) + int64(value.Len())
+	}
+	for c.maxBytes != 0 && c.maxBytes < c.nbytes {
+		c.Evict()
+	}
+}
+
+// Get 返回 key 对应的值，不会影响其淘汰顺序
+func (c *FIFOPolicy) Get(key string) (value Value, ok bool) {
+	if ele, ok := c.cache[key]; ok {
+		return ele.Value.(*fifoEntry).value, true
+	}
+	return
+}
+
+// Peek 返回 key 对应的值，和 Get 一样不影响其淘汰顺序
+func (c *FIFOPolicy) Peek(key string) (value Value, ok bool) {
+	return c.Get(key)
+}
+
+// Evict 淘汰最早写入的条目
+func (c *FIFOPolicy) Evict() (key string, value Value, ok bool) {
+	ele := c.ll.Back()
+	if ele == nil {
+		return "", nil, false
+	}
+	c.ll.Remove(ele)
+	kv := ele.Value.(*fifoEntry)
+	delete(c.cache, kv.key)
+	c.nbytes -= int64(len(kv.key)) + int64(kv.value.Len())
+	if c.OnEvicted != nil {
+		c.OnEvicted(kv.key, kv.value)
+	}
+	return kv.key, kv.value, true
+}
+
+// Remove 显式移除指定 key
+func (c *FIFOPolicy) Remove(key string) (value Value, ok bool) {
+	ele, exists := c.cache[key]
+	if !exists {
+		return nil, false
+	}
+	c.ll.Remove(ele)
+	kv := ele.Value.(*fifoEntry)
+	delete(c.cache, kv.key)
+	c.nbytes -= int64(len(kv.key)) + int64(kv.value.Len())
+	return kv.value, true
+}
+
+// Sample 返回至多 n 个当前存储的 key，供后台主动过期扫描使用
+func (c *FIFOPolicy) Sample(n int) []string {
+	keys := make([]string, 0, n)
+	for k := range c.cache {
+		if len(keys) >= n {
+			break
+		}
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+// Len 返回 Cache 中元素的数量
+func (c *FIFOPolicy) Len() int {
+	return c.ll.Len()
+}
+
+// Bytes 返回当前占用的字节数
+func (c *FIFOPolicy) Bytes() int64 {
+	return c.nbytes
+}
+
+var _ Policy = (*FIFOPolicy)(nil)
@@ -0,0 +1,174 @@
+package lru
+
+import "container/list"
+
+// freqNode 代表一个访问频次桶，freqList 中的桶按 freq 升序排列
+type freqNode struct {
+	freq  int
+	items *list.List // 存放频次恰好为 freq 的 *lfuEntry
+}
+
+// lfuEntry 是某个频次桶中存储的条目
+type lfuEntry struct {
+	key      string
+	value    Value
+	freqNode *list.Element // 所在的 freqNode 在 freqList 中的位置
+	itemElem *list.Element // 自身在 freqNode.items 中的位置
+}
+
+// LFUPolicy 实现了最不经常使用（LFU）淘汰策略。借助按访问频次分桶的双向链表
+// （Ketan Shah 提出的经典 O(1) LFU 结构），Get/Add/Evict 都不需要遍历或维护堆，
+// 均为 O(1) 复杂度。
+type LFUPolicy struct {
+	maxBytes  int64
+	nbytes    int64
+	freqList  *list.List // 按 freq 升序排列的 *freqNode
+	items     map[string]*lfuEntry
+	OnEvicted func(key string, value Value)
+}
+
+// NewLFUPolicy 创建一个 LFUPolicy，maxBytes 为 0 表示不限制容量
+func NewLFUPolicy(maxBytes int64, onEvicted func(key string, value Value)) Policy {
+	return &LFUPolicy{
+		maxBytes:  maxBytes,
+		freqList:  list.New(),
+		items:     make(map[string]*lfuEntry),
+		OnEvicted: onEvicted,
+	}
+}
+
+// incrFreq 将条目从当前所在的频次桶移动到 freq+1 的桶，必要时新建桶，
+// 并在原桶变空时将其从 freqList 中移除
+func (c *LFUPolicy) incrFreq(e *lfuEntry) {
+	curNode := e.freqNode.Value.(*freqNode)
+	nextFreq := curNode.freq + 1
+
+	var nextNode *list.Element
+	if next := e.freqNode.Next(); next != nil && next.Value.(*freqNode).freq == nextFreq {
+		nextNode = next
+	} else {
+		nextNode = c.freqList.InsertAfter(&freqNode{freq: nextFreq, items: list.New()}, e.freqNode)
+	}
+
+	curNode.items.Remove(e.itemElem)
+	if curNode.items.Len() == 0 {
+		c.freqList.Remove(e.freqNode)
+	}
+
+	fn := nextNode.Value.(*freqNode)
+	e.itemElem = fn.items.PushFront(e)
+	e.freqNode = nextNode
+}
+
+// Add 写入或更新一个键值对。已存在的 key 会被视为一次访问，频次 +1。
+func (c *LFUPolicy) Add(key string, value Value) {
+	if e, ok := c.items[key]; ok {
+		c.nbytes += int64(value.Len()) - int64(e.value.Len())
+		e.value = value
+		c.incrFreq(e)
+		c.evictUntilFits()
+		return
+	}
+
+	var firstNode *list.Element
+	if front := c.freqList.Front(); front != nil && front.Value.(*freqNode).freq == 1 {
+		firstNode = front
+	} else {
+		firstNode = c.freqList.PushFront(&freqNode{freq: 1, items: list.New()})
+	}
+
+	e := &lfuEntry{key: key, value: value, freqNode: firstNode}
+	e.itemElem = firstNode.Value.(*freqNode).items.PushFront(e)
+	c.items[key] = e
+	c.nbytes += int64(len(key)) + int64(value.Len())
+
+	c.evictUntilFits()
+}
+
+func (c *LFUPolicy) evictUntilFits() {
+	for c.maxBytes != 0 && c.maxBytes < c.nbytes {
+		c.Evict()
+	}
+}
+
+// Get 返回 key 对应的值，命中时该条目的访问频次 +1
+func (c *LFUPolicy) Get(key string) (value Value, ok bool) {
+	e, ok := c.items[key]
+	if !ok {
+		return nil, false
+	}
+	c.incrFreq(e)
+	return e.value, true
+}
+
+// Peek 返回 key 对应的值，不改变其访问频次
+func (c *LFUPolicy) Peek(key string) (value Value, ok bool) {
+	e, ok := c.items[key]
+	if !ok {
+		return nil, false
+	}
+	return e.value, true
+}
+
+// Evict 淘汰当前访问频次最低的桶中最久未被访问的条目
+func (c *LFUPolicy) Evict() (key string, value Value, ok bool) {
+	front := c.freqList.Front()
+	if front == nil {
+		return "", nil, false
+	}
+	fn := front.Value.(*freqNode)
+	back := fn.items.Back()
+	e := back.Value.(*lfuEntry)
+
+	fn.items.Remove(back)
+	if fn.items.Len() == 0 {
+		c.freqList.Remove(front)
+	}
+	delete(c.items, e.key)
+	c.nbytes -= int64(len(e.key)) + int64(e.value.Len())
+
+	if c.OnEvicted != nil {
+		c.OnEvicted(e.key, e.value)
+	}
+	return e.key, e.value, true
+}
+
+// Remove 显式移除指定 key
+func (c *LFUPolicy) Remove(key string) (value Value, ok bool) {
+	e, exists := c.items[key]
+	if !exists {
+		return nil, false
+	}
+	fn := e.freqNode.Value.(*freqNode)
+	fn.items.Remove(e.itemElem)
+	if fn.items.Len() == 0 {
+		c.freqList.Remove(e.freqNode)
+	}
+	delete(c.items, key)
+	c.nbytes -= int64(len(key)) + int64(e.value.Len())
+	return e.value, true
+}
+
+// Sample 返回至多 n 个当前存储的 key，供后台主动过期扫描使用
+func (c *LFUPolicy) Sample(n int) []string {
+	keys := make([]string, 0, n)
+	for k := range c.items {
+		if len(keys) >= n {
+			break
+		}
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+// Len 返回 Cache 中元素的数量
+func (c *LFUPolicy) Len() int {
+	return len(c.items)
+}
+
+// Bytes 返回当前占用的字节数
+func (c *LFUPolicy) Bytes() int64 {
+	return c.nbytes
+}
+
+var _ Policy = (*LFUPolicy)(nil)
@@ -2,29 +2,24 @@ package lru
 
 import "container/list"
 
-// lru 包实现了使用最近最久未使用使用算法的缓存功能
-type Cache struct {
-	maxBytes  int64                         // Cache 最大容量(Byte)
-	nbytes    int64                         // Cache 当前容量(Byte)
-	ll        *list.List                    // 双向链表，用于存储缓存的键值对
-	cache     map[string]*list.Element      // 用于存储键值对在双向链表中的节点地址
-	OnEvicted func(key string, value Value) // 可选的，在清除条目时执行
+// LRUPolicy 实现了最近最久未使用（LRU）淘汰策略
+type LRUPolicy struct {
+	maxBytes  int64                         // 容量上限(Byte)，为 0 表示不限制
+	nbytes    int64                         // 当前占用容量(Byte)
+	ll        *list.List                    // 双向链表，用于维护访问顺序
+	cache     map[string]*list.Element      // 保存键到链表节点的映射
+	OnEvicted func(key string, value Value) // 可选，在条目被淘汰时触发
 }
 
-// 定义双向链表节点所存储的对象
-type entry struct {
+// lruEntry 是双向链表节点所存储的对象
+type lruEntry struct {
 	key   string
 	value Value
 }
 
-// 定义 Value 接口，用于计算所存储的对象所占用的内存大小
-type Value interface {
-	Len() int
-}
-
-// 初始化 Cache
-func New(maxBytes int64, onEvicted func(string, Value)) *Cache {
-	return &Cache{
+// NewLRUPolicy 创建一个 LRUPolicy，maxBytes 为 0 表示不限制容量
+func NewLRUPolicy(maxBytes int64, onEvicted func(key string, value Value)) Policy {
+	return &LRUPolicy{
 		maxBytes:  maxBytes,
 		ll:        list.New(),
 		cache:     make(map[string]*list.Element),
@@ -32,48 +27,101 @@ func New(maxBytes int64, onEvicted func(string, Value)) *Cache {
 	}
 }
 
-// 向 Cache 中添加一个元素
-func (c *Cache) Add(key string, value Value) {
+// Add 写入或更新一个键值对，并在超出容量时淘汰最久未被访问的条目
+func (c *LRUPolicy) Add(key string, value Value) {
 	if ele, ok := c.cache[key]; ok {
 		c.ll.MoveToFront(ele)
-		kv := ele.Value.(*entry)
+		kv := ele.Value.(*lruEntry)
 		c.nbytes += int64(value.Len()) - int64(kv.value.Len())
 		kv.value = value
 	} else {
-		ele := c.ll.PushFront(&entry{key, value})
+		ele := c.ll.PushFront(&lruEntry{key, value})
 		c.cache[key] = ele
 		c.nbytes += int64(len(key)) + int64(value.Len())
 	}
 	for c.maxBytes != 0 && c.maxBytes < c.nbytes {
-		c.RemoveOldest()
+		c.Evict()
 	}
 }
 
-// 从 Cache 中获取一个元素
-func (c *Cache) Get(key string) (value Value, ok bool) {
+// Get 返回 key 对应的值，并将其移动到链表头部
+func (c *LRUPolicy) Get(key string) (value Value, ok bool) {
 	if ele, ok := c.cache[key]; ok {
 		c.ll.MoveToFront(ele)
-		kv := ele.Value.(*entry)
+		kv := ele.Value.(*lruEntry)
 		return kv.value, true
 	}
 	return
 }
 
-// 从 Cache 中删除最近最久未使用的元素
-func (c *Cache) RemoveOldest() {
+// Peek 返回 key 对应的值，不改变其访问顺序
+func (c *LRUPolicy) Peek(key string) (value Value, ok bool) {
+	if ele, ok := c.cache[key]; ok {
+		return ele.Value.(*lruEntry).value, true
+	}
+	return
+}
+
+// Evict 淘汰链表尾部，即最近最久未被访问的条目
+func (c *LRUPolicy) Evict() (key string, value Value, ok bool) {
 	ele := c.ll.Back()
-	if ele != nil {
-		c.ll.Remove(ele)
-		kv := ele.Value.(*entry)
-		delete(c.cache, kv.key)
-		c.nbytes -= int64(len(kv.key)) + int64(kv.value.Len())
-		if c.OnEvicted != nil {
-			c.OnEvicted(kv.key, kv.value)
-		}
+	if ele == nil {
+		return "", nil, false
+	}
+	c.ll.Remove(ele)
+	kv := ele.Value.(*lruEntry)
+	delete(c.cache, kv.key)
+	c.nbytes -= int64(len(kv.key)) + int64(kv.value.Len())
+	if c.OnEvicted != nil {
+		c.OnEvicted(kv.key, kv.value)
 	}
+	return kv.key, kv.value, true
 }
 
-// 获取 Cache 中元素的数量
-func (c *Cache) Len() int {
+// Len 返回 Cache 中元素的数量
+func (c *LRUPolicy) Len() int {
 	return c.ll.Len()
 }
+
+// Bytes 返回当前占用的字节数
+func (c *LRUPolicy) Bytes() int64 {
+	return c.nbytes
+}
+
+// peekBack 返回链表尾部的键值对但不移除，供 TinyLFUPolicy 在准入判断时查看
+// 淘汰候选者而不实际改变其状态
+func (c *LRUPolicy) peekBack() (key string, value Value, ok bool) {
+	ele := c.ll.Back()
+	if ele == nil {
+		return "", nil, false
+	}
+	kv := ele.Value.(*lruEntry)
+	return kv.key, kv.value, true
+}
+
+// Remove 显式移除指定 key，不影响其余条目的访问顺序
+func (c *LRUPolicy) Remove(key string) (value Value, ok bool) {
+	ele, exists := c.cache[key]
+	if !exists {
+		return nil, false
+	}
+	c.ll.Remove(ele)
+	kv := ele.Value.(*lruEntry)
+	delete(c.cache, kv.key)
+	c.nbytes -= int64(len(kv.key)) + int64(kv.value.Len())
+	return kv.value, true
+}
+
+// Sample 返回至多 n 个当前存储的 key，供后台主动过期扫描使用
+func (c *LRUPolicy) Sample(n int) []string {
+	keys := make([]string, 0, n)
+	for k := range c.cache {
+		if len(keys) >= n {
+			break
+		}
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+var _ Policy = (*LRUPolicy)(nil)
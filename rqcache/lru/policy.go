@@ -0,0 +1,45 @@
+// Package lru 提供了一组可插拔的缓存淘汰策略（LRU、LFU、FIFO、W-TinyLFU），
+// 供上层的 cache 按字节容量选择使用。
+//
+// 几种策略的取舍：LRUPolicy 实现简单、对时间局部性友好，但容易被一次性的
+// 批量扫描冲刷掉热点数据；LFUPolicy 更贴近长期访问频率，但对访问模式的
+// 突变反应迟钝；FIFOPolicy 完全不考虑访问情况，只适合对命中率没有要求、
+// 只求实现简单的场景。TinyLFUPolicy 用一个很小的 window LRU 吸收突发扫描，
+// 再用 Count-Min Sketch 估计的历史频率作为准入过滤器筛选进入 main cache 的
+// 条目，在 Zipfian 分布（少量 key 占据绝大多数访问）的工作负载下，相比单纯
+// LRU 通常能获得更高的命中率，因为它不会被一次性的冷扫描吃掉本该长期驻留的
+// 热点 key。
+package lru
+
+// Value 接口用于计算所存储对象所占用的内存大小
+type Value interface {
+	Len() int
+}
+
+// Policy 是可插拔的缓存淘汰策略需要实现的接口，cache 通过它来决定数据该如何
+// 增删，而不关心具体使用哪一种淘汰算法
+type Policy interface {
+	// Add 写入或更新一个键值对，如果超出容量会触发淘汰
+	Add(key string, value Value)
+	// Get 返回 key 对应的值，命中时会按策略更新该条目的状态（如最近访问时间、访问频次）
+	Get(key string) (value Value, ok bool)
+	// Peek 返回 key 对应的值，但不改变该条目在淘汰策略中的状态，供不应被
+	// 当作一次访问的场景使用（如后台 TTL 过期扫描）
+	Peek(key string) (value Value, ok bool)
+	// Evict 按该策略的淘汰顺序主动淘汰一个条目，并触发 OnEvicted
+	Evict() (key string, value Value, ok bool)
+	// Remove 显式移除指定 key，不影响淘汰顺序，主要供上层在条目过期或被
+	// 主动失效时调用
+	Remove(key string) (value Value, ok bool)
+	// Sample 返回至多 n 个当前存储的 key，用于后台主动过期等不依赖正常读写
+	// 路径的抽样扫描，不保证严格随机，只要求近似均匀
+	Sample(n int) []string
+	// Len 返回当前存储的条目数量
+	Len() int
+	// Bytes 返回当前占用的字节数
+	Bytes() int64
+}
+
+// PolicyFactory 用于创建一个新的 Policy 实例。maxBytes 为该策略的容量上限
+// （0 表示不限制），onEvicted 在条目被淘汰时触发，可以为 nil。
+type PolicyFactory func(maxBytes int64, onEvicted func(key string, value Value)) Policy
@@ -0,0 +1,51 @@
+package lru
+
+import (
+	"math/rand"
+	"strconv"
+	"testing"
+)
+
+// benchValue 是一个固定大小的 Value 实现，只用于基准测试衡量命中率，不关心
+// 实际负载内容
+type benchValue struct{}
+
+func (benchValue) Len() int { return 1 }
+
+// zipfianHitRate 在 Zipfian 分布（少数 key 占据绝大多数访问）下模拟
+// numAccesses 次 Get-or-Add 访问，返回命中率
+func zipfianHitRate(newPolicy PolicyFactory, maxBytes int64, numKeys, numAccesses int) float64 {
+	r := rand.New(rand.NewSource(1))
+	z := rand.NewZipf(r, 1.5, 1, uint64(numKeys-1))
+
+	p := newPolicy(maxBytes, nil)
+	hits := 0
+	for i := 0; i < numAccesses; i++ {
+		key := strconv.FormatUint(z.Uint64(), 10)
+		if _, ok := p.Get(key); ok {
+			hits++
+			continue
+		}
+		p.Add(key, benchValue{})
+	}
+	return float64(hits) / float64(numAccesses)
+}
+
+// TestTinyLFUBeatsLRUOnZipfian 是 chunk0-2 要求的基准测试：在同一个偏斜的
+// Zipfian 工作负载、同样的容量下，W-TinyLFU 凭借频次信息识别真正的热点 key，
+// 命中率不应低于朴素 LRU——这正是引入它的理由。
+func TestTinyLFUBeatsLRUOnZipfian(t *testing.T) {
+	const (
+		numKeys     = 1000
+		numAccesses = 200000
+	)
+	maxBytes := int64(numKeys) / 10 * 5 // 容量只够装下约 15% 的 key（约 150 个），逼出淘汰
+
+	lruHitRate := zipfianHitRate(NewLRUPolicy, maxBytes, numKeys, numAccesses)
+	tinyLFUHitRate := zipfianHitRate(NewTinyLFUPolicy, maxBytes, numKeys, numAccesses)
+
+	t.Logf("zipfian 命中率：lru=%.4f tinylfu=%.4f", lruHitRate, tinyLFUHitRate)
+	if tinyLFUHitRate < lruHitRate {
+		t.Fatalf("expected TinyLFU hit rate (%.4f) >= LRU hit rate (%.4f) on a Zipfian workload", tinyLFUHitRate, lruHitRate)
+	}
+}
@@ -0,0 +1,212 @@
+package lru
+
+const (
+	tinyLFUWindowRatio    = 0.01 // window LRU 占总容量的比例
+	tinyLFUProtectedRatio = 0.8  // main 段内 protected:probation 的容量比例
+
+	// entrySizeEstimate 用来把字节容量换算成 Count-Min Sketch 的计数器数量，
+	// 按平均条目大小估算，不要求精确
+	entrySizeEstimate = 128
+
+	// sketchMinWidth 是 Count-Min Sketch 宽度的下限：当 maxBytes 很小或条目
+	// 本身很小（entrySizeEstimate 这个平均值假设明显偏大）时，按
+	// entrySizeEstimate 估算出的宽度可能只有几个槽位，导致哈希碰撞严重、
+	// 频次估计失真，因此用一个下限兜底
+	sketchMinWidth = 1024
+)
+
+// TinyLFUPolicy 实现了 W-TinyLFU 淘汰策略：一个很小的 window LRU（约占总容量
+// 的 1%）吸收突发的一次性扫描式访问，其被淘汰的候选者需要与 main cache（按
+// protected/probation 分段的 SLRU）的淘汰候选者比较 Count-Min Sketch 估计的
+// 历史访问频次，只有频次更高的一方才能进入 main cache，频次较低的一方直接
+// 丢弃。相比单纯 LRU，这让缓存在 Zipfian 这类长尾分布的工作负载下更不容易
+// 被低频的一次性访问冲刷掉真正的热点 key。
+type TinyLFUPolicy struct {
+	window    *LRUPolicy // 吸收突发访问的小型 LRU
+	protected *LRUPolicy // SLRU 的 protected 段：被再次访问过的 main 条目
+	probation *LRUPolicy // SLRU 的 probation 段：刚从 window 准入、尚未被复访的条目
+
+	sketch *countMinSketch
+
+	OnEvicted func(key string, value Value)
+}
+
+// NewTinyLFUPolicy 创建一个 TinyLFUPolicy，maxBytes 为 0 表示不限制容量
+func NewTinyLFUPolicy(maxBytes int64, onEvicted func(key string, value Value)) Policy {
+	windowBytes := int64(float64(maxBytes) * tinyLFUWindowRatio)
+	mainBytes := maxBytes - windowBytes
+	protectedBytes := int64(float64(mainBytes) * tinyLFUProtectedRatio)
+	probationBytes := mainBytes - protectedBytes
+
+	sketchWidth := int(maxBytes/entrySizeEstimate) + 1
+	if sketchWidth < sketchMinWidth {
+		sketchWidth = sketchMinWidth
+	}
+
+	p := &TinyLFUPolicy{
+		sketch:    newCountMinSketch(sketchWidth, sketchWidth*10),
+		OnEvicted: onEvicted,
+	}
+	p.probation = NewLRUPolicy(probationBytes, p.onProbationEvicted).(*LRUPolicy)
+	p.protected = NewLRUPolicy(protectedBytes, p.demoteToProbation).(*LRUPolicy)
+	p.window = NewLRUPolicy(windowBytes, p.admit).(*LRUPolicy)
+	return p
+}
+
+// demoteToProbation 是 protected 段的 OnEvicted 回调：protected 容量已满时，
+// 被挤出的条目并未真正淘汰，而是退回 probation 段重新排队
+func (p *TinyLFUPolicy) demoteToProbation(key string, value Value) {
+	p.probation.Add(key, value)
+}
+
+// onProbationEvicted 是 probation 段自身容量不足导致的真实淘汰（而非准入
+// 竞争失败），向外层转发
+func (p *TinyLFUPolicy) onProbationEvicted(key string, value Value) {
+	if p.OnEvicted != nil {
+		p.OnEvicted(key, value)
+	}
+}
+
+// admit 是 window 段的 OnEvicted 回调：window 容量已满时，被挤出的候选者
+// 本该进入 probation 段。如果 probation 还有空间，直接放行；只有当放行会
+// 挤满 probation 时，才与其当前最久未访问的条目比较 Count-Min Sketch 估计
+// 的频次，胜者进入 probation，败者被直接丢弃。
+func (p *TinyLFUPolicy) admit(candidateKey string, candidateValue Value) {
+	candidateSize := int64(len(candidateKey)) + int64(candidateValue.Len())
+	if p.probation.maxBytes == 0 || p.probation.Bytes()+candidateSize <= p.probation.maxBytes {
+		p.probation.Add(candidateKey, candidateValue)
+		return
+	}
+
+	victimKey, victimValue, ok := p.probation.peekBack()
+	if !ok {
+		p.probation.Add(candidateKey, candidateValue)
+		return
+	}
+
+	if p.sketch.get(candidateKey) > p.sketch.get(victimKey) {
+		p.probation.Remove(victimKey)
+		if p.OnEvicted != nil {
+			p.OnEvicted(victimKey, victimValue)
+		}
+		p.probation.Add(candidateKey, candidateValue)
+		return
+	}
+
+	if p.OnEvicted != nil {
+		p.OnEvicted(candidateKey, candidateValue)
+	}
+}
+
+// Add 写入或更新一个键值对
+func (p *TinyLFUPolicy) Add(key string, value Value) {
+	p.sketch.add(key)
+
+	if _, ok := p.protected.Get(key); ok {
+		p.protected.Add(key, value)
+		return
+	}
+	if _, ok := p.probation.Remove(key); ok {
+		p.protected.Add(key, value)
+		return
+	}
+
+	p.window.Add(key, value)
+}
+
+// Get 返回 key 对应的值。命中 probation 段时会将其提升到 protected 段。
+func (p *TinyLFUPolicy) Get(key string) (value Value, ok bool) {
+	p.sketch.add(key)
+
+	if v, ok := p.protected.Get(key); ok {
+		return v, true
+	}
+	if v, ok := p.probation.Remove(key); ok {
+		p.protected.Add(key, v)
+		return v, true
+	}
+	if v, ok := p.window.Get(key); ok {
+		return v, true
+	}
+	return nil, false
+}
+
+// Peek 返回 key 对应的值，不提升 probation 条目、不更新 Count-Min Sketch 的
+// 频次估计
+func (p *TinyLFUPolicy) Peek(key string) (value Value, ok bool) {
+	if v, ok := p.protected.Peek(key); ok {
+		return v, true
+	}
+	if v, ok := p.probation.Peek(key); ok {
+		return v, true
+	}
+	if v, ok := p.window.Peek(key); ok {
+		return v, true
+	}
+	return nil, false
+}
+
+// Evict 依次从 window、probation、protected 中淘汰一个条目：条目直接移出
+// 所在段（不经过段间的准入或降级），并触发一次 OnEvicted
+func (p *TinyLFUPolicy) Evict() (key string, value Value, ok bool) {
+	if k, v, ok := p.window.peekBack(); ok {
+		p.window.Remove(k)
+		if p.OnEvicted != nil {
+			p.OnEvicted(k, v)
+		}
+		return k, v, true
+	}
+	if k, v, ok := p.probation.peekBack(); ok {
+		p.probation.Remove(k)
+		if p.OnEvicted != nil {
+			p.OnEvicted(k, v)
+		}
+		return k, v, true
+	}
+	if k, v, ok := p.protected.peekBack(); ok {
+		p.protected.Remove(k)
+		if p.OnEvicted != nil {
+			p.OnEvicted(k, v)
+		}
+		return k, v, true
+	}
+	return "", nil, false
+}
+
+// Remove 从三段中任意一段移除指定 key
+func (p *TinyLFUPolicy) Remove(key string) (value Value, ok bool) {
+	if v, ok := p.protected.Remove(key); ok {
+		return v, true
+	}
+	if v, ok := p.probation.Remove(key); ok {
+		return v, true
+	}
+	if v, ok := p.window.Remove(key); ok {
+		return v, true
+	}
+	return nil, false
+}
+
+// Sample 按各段当前条目数的比例从三段中取 key，合计至多 n 个
+func (p *TinyLFUPolicy) Sample(n int) []string {
+	total := p.Len()
+	if total == 0 || n <= 0 {
+		return nil
+	}
+	keys := p.window.Sample(n * p.window.Len() / total)
+	keys = append(keys, p.probation.Sample(n*p.probation.Len()/total)...)
+	keys = append(keys, p.protected.Sample(n-len(keys))...)
+	return keys
+}
+
+// Len 返回三段中元素数量之和
+func (p *TinyLFUPolicy) Len() int {
+	return p.window.Len() + p.probation.Len() + p.protected.Len()
+}
+
+// Bytes 返回三段当前占用字节数之和
+func (p *TinyLFUPolicy) Bytes() int64 {
+	return p.window.Bytes() + p.probation.Bytes() + p.protected.Bytes()
+}
+
+var _ Policy = (*TinyLFUPolicy)(nil)
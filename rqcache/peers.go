@@ -0,0 +1,27 @@
+package rqcache
+
+import (
+	"context"
+
+	pb "rqcache/rqcachepb"
+)
+
+// PeerPicker 用于根据传入的 key 选择相应的同伴节点 PeerGetter
+type PeerPicker interface {
+	PickPeer(key string) (peer PeerGetter, ok bool)
+}
+
+// PeerGetter 定义了从同伴节点获取缓存值的能力，对应于具体的某一个远程节点。
+// ctx 的截止时间/取消信号会被具体实现（httpGetter、grpcGetter）透传给底层请求。
+type PeerGetter interface {
+	Get(ctx context.Context, in *pb.Request, out *pb.Response) error
+}
+
+// PeerInvalidator 是部分 PeerPicker 实现额外具备的能力：向集群中已知的全部
+// 同伴节点（而不仅是某个 key 命中的那一个）广播一次写失效通知，配合
+// Group.Update/Group.Remove 使用，实现 opt-in 的写一致性。HTTPPool 实现了该
+// 接口；只处理只读路径的 PeerPicker 实现（例如测试用的 stub）不需要实现它——
+// Group 在广播前会用类型断言检测 g.peers 是否支持这个能力。
+type PeerInvalidator interface {
+	InvalidatePeers(ctx context.Context, group, key string, version int64)
+}
@@ -0,0 +1,24 @@
+package rqcache
+
+import "context"
+
+// PeerRegistry 用于发现集群当前存活的同伴节点地址列表，并在节点加入、离开或
+// 被判定为失活时推送最新列表，替代了在静态配置里手工维护、需要每次变更都
+// 重新调用 HTTPPool.Set/GRPCPool.Set 的做法。
+type PeerRegistry interface {
+	// Watch 返回一个 channel，初次订阅时立即推送一份当前成员列表，此后每当
+	// 成员发生变化（节点加入、主动离开或租约/心跳超时）时再推送一份最新的
+	// 完整列表（不包含调用方自身）。ctx 被取消时该 channel 会被关闭。
+	Watch(ctx context.Context) <-chan []string
+}
+
+// usePeerRegistry 是 HTTPPool/GRPCPool 共用的装配逻辑：订阅 reg 推送的成员
+// 列表，并在每次收到更新时调用 apply 重建节点池，直到 ctx 被取消为止。
+func usePeerRegistry(ctx context.Context, reg PeerRegistry, apply func(peers []string)) {
+	ch := reg.Watch(ctx)
+	go func() {
+		for peers := range ch {
+			apply(peers)
+		}
+	}()
+}
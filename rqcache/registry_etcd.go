@@ -0,0 +1,118 @@
+package rqcache
+
+import (
+	"context"
+	"fmt"
+
+	clientv3 "go.etcd.io/etcd/client/v3"
+)
+
+// etcd 中用于存放节点地址的默认前缀，以及默认的租约 TTL（秒）
+const (
+	defaultEtcdPeerPrefix = "/rqcache/peers/"
+	defaultEtcdLeaseTTL   = 10
+)
+
+// EtcdRegistry 基于 etcd v3 实现 PeerRegistry：每个节点在 prefix+selfID 下
+// 注册自己的地址，并绑定一个带 TTL 的租约定期续约（心跳）。一旦节点异常退出、
+// 未能及时续约，租约到期后 etcd 会自动删除对应的 key，其余节点通过 Watch
+// 感知到成员变化，成员关系由实际存活状态驱动，而不是人工推送的配置。
+type EtcdRegistry struct {
+	client   *clientv3.Client
+	prefix   string
+	selfID   string
+	selfAddr string
+	leaseTTL int64
+}
+
+// NewEtcdRegistry 创建一个 EtcdRegistry。selfID 是本节点在 etcd 中的唯一标识
+// （例如主机名或实例 ID），selfAddr 是其他节点应当用来访问本节点的地址；
+// selfAddr 为空表示本节点只观察成员列表、不注册自己（例如一个旁路的监控/
+// 路由节点）。
+func NewEtcdRegistry(client *clientv3.Client, selfID, selfAddr string) *EtcdRegistry {
+	return &EtcdRegistry{
+		client:   client,
+		prefix:   defaultEtcdPeerPrefix,
+		selfID:   selfID,
+		selfAddr: selfAddr,
+		leaseTTL: defaultEtcdLeaseTTL,
+	}
+}
+
+// register 在 etcd 下登记本节点的地址并持续续约，直到 ctx 被取消
+func (r *EtcdRegistry) register(ctx context.Context) error {
+	lease, err := r.client.Grant(ctx, r.leaseTTL)
+	if err != nil {
+		return fmt.Errorf("创建租约失败：%w", err)
+	}
+
+	key := r.prefix + r.selfID
+	if _, err := r.client.Put(ctx, key, r.selfAddr, clientv3.WithLease(lease.ID)); err != nil {
+		return fmt.Errorf("注册节点失败：%w", err)
+	}
+
+	keepAlive, err := r.client.KeepAlive(ctx, lease.ID)
+	if err != nil {
+		return fmt.Errorf("续约失败：%w", err)
+	}
+	go func() {
+		// 持续消费续约响应；ctx 取消后该 channel 会被关闭，租约随后到期，
+		// etcd 自动删除本节点的 key，其余节点的 Watch 会感知到节点离开
+		for range keepAlive {
+		}
+	}()
+	return nil
+}
+
+// Watch 实现 PeerRegistry：先注册自身（若 selfAddr 非空），随后监听 prefix
+// 下的全部变化，每次变化都重新拉取一份完整列表并推送（排除自身）
+func (r *EtcdRegistry) Watch(ctx context.Context) <-chan []string {
+	out := make(chan []string)
+
+	go func() {
+		defer close(out)
+
+		if r.selfAddr != "" {
+			if err := r.register(ctx); err != nil {
+				return
+			}
+		}
+
+		push := func() {
+			resp, err := r.client.Get(ctx, r.prefix, clientv3.WithPrefix())
+			if err != nil {
+				return
+			}
+			peers := make([]string, 0, len(resp.Kvs))
+			for _, kv := range resp.Kvs {
+				addr := string(kv.Value)
+				if addr == r.selfAddr {
+					continue
+				}
+				peers = append(peers, addr)
+			}
+			select {
+			case out <- peers:
+			case <-ctx.Done():
+			}
+		}
+		push()
+
+		watchCh := r.client.Watch(ctx, r.prefix, clientv3.WithPrefix())
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case _, ok := <-watchCh:
+				if !ok {
+					return
+				}
+				push()
+			}
+		}
+	}()
+
+	return out
+}
+
+var _ PeerRegistry = (*EtcdRegistry)(nil)
@@ -0,0 +1,123 @@
+package rqcache
+
+import (
+	"context"
+	"sync"
+
+	"github.com/hashicorp/memberlist"
+)
+
+// GossipRegistry 基于 memberlist（SWIM 协议）实现 PeerRegistry，适用于没有
+// etcd 等外部协调服务的部署：节点之间通过周期性的 ping/indirect-ping 以及
+// 反熵同步直接感知彼此的存活状态，成员关系不依赖任何中心化组件。
+type GossipRegistry struct {
+	list     *memberlist.Memberlist
+	selfAddr string
+
+	mu   sync.Mutex
+	subs []chan []string
+}
+
+// NewGossipRegistry 创建并加入一个 gossip 集群。bindAddr/bindPort 是本节点
+// gossip 协议监听的地址，selfAddr 是其他节点应当用来访问本节点缓存服务的
+// 地址（随成员信息一并广播给其他节点），seeds 是用于加入已有集群的一个或
+// 多个已知成员地址；seeds 为空时本节点作为集群的第一个成员启动。
+func NewGossipRegistry(bindAddr string, bindPort int, selfAddr string, seeds []string) (*GossipRegistry, error) {
+	r := &GossipRegistry{selfAddr: selfAddr}
+
+	cfg := memberlist.DefaultLANConfig()
+	cfg.BindAddr = bindAddr
+	cfg.BindPort = bindPort
+	cfg.Delegate = &gossipDelegate{selfAddr: selfAddr}
+	cfg.Events = &gossipEventDelegate{registry: r}
+
+	list, err := memberlist.Create(cfg)
+	if err != nil {
+		return nil, err
+	}
+	r.list = list
+
+	if len(seeds) > 0 {
+		if _, err := list.Join(seeds); err != nil {
+			return nil, err
+		}
+	}
+
+	return r, nil
+}
+
+// Watch 实现 PeerRegistry：立即推送一份当前成员列表，此后每当
+// gossipEventDelegate 感知到成员加入、主动离开或被判定为失活时再推送一份
+func (r *GossipRegistry) Watch(ctx context.Context) <-chan []string {
+	ch := make(chan []string, 1)
+
+	r.mu.Lock()
+	r.subs = append(r.subs, ch)
+	r.mu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		for i, sub := range r.subs {
+			if sub == ch {
+				r.subs = append(r.subs[:i], r.subs[i+1:]...)
+				break
+			}
+		}
+		close(ch)
+	}()
+
+	r.broadcast()
+	return ch
+}
+
+// broadcast 把当前成员列表（排除自身）推送给所有订阅者；订阅者消费不及时
+// 时直接丢弃这一次推送，因为很快会有新的成员快照取代它
+func (r *GossipRegistry) broadcast() {
+	members := r.list.Members()
+	peers := make([]string, 0, len(members))
+	for _, member := range members {
+		addr := string(member.Meta)
+		if addr == "" || addr == r.selfAddr {
+			continue
+		}
+		peers = append(peers, addr)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, sub := range r.subs {
+		select {
+		case sub <- peers:
+		default:
+		}
+	}
+}
+
+var _ PeerRegistry = (*GossipRegistry)(nil)
+
+// gossipDelegate 把本节点的缓存服务地址作为 node meta 随 gossip 协议广播出去，
+// 其余方法是 memberlist.Delegate 接口要求但本场景不需要的能力的空实现
+type gossipDelegate struct {
+	selfAddr string
+}
+
+func (d *gossipDelegate) NodeMeta(limit int) []byte                  { return []byte(d.selfAddr) }
+func (d *gossipDelegate) NotifyMsg([]byte)                           {}
+func (d *gossipDelegate) GetBroadcasts(overhead, limit int) [][]byte { return nil }
+func (d *gossipDelegate) LocalState(join bool) []byte                { return nil }
+func (d *gossipDelegate) MergeRemoteState(buf []byte, join bool)     {}
+
+var _ memberlist.Delegate = (*gossipDelegate)(nil)
+
+// gossipEventDelegate 把 memberlist 的成员变化事件转发给 GossipRegistry.broadcast
+type gossipEventDelegate struct {
+	registry *GossipRegistry
+}
+
+func (d *gossipEventDelegate) NotifyJoin(*memberlist.Node)   { d.registry.broadcast() }
+func (d *gossipEventDelegate) NotifyLeave(*memberlist.Node)  { d.registry.broadcast() }
+func (d *gossipEventDelegate) NotifyUpdate(*memberlist.Node) { d.registry.broadcast() }
+
+var _ memberlist.EventDelegate = (*gossipEventDelegate)(nil)
@@ -0,0 +1,89 @@
+// Package rqcachepb 定义 HTTPPool/GRPCPool 与同伴节点之间交互所用的请求/
+// 响应结构体，以及 Cache 的 gRPC 服务接口。这些类型对应 rqcachepb.proto，
+// 但是手写的，不经 protoc 生成；修改 .proto 后需要同步手动更新本包。
+package rqcachepb
+
+import proto "github.com/golang/protobuf/proto"
+
+// Request 表示一次缓存查询请求
+type Request struct {
+	Group string `protobuf:"bytes,1,opt,name=group,proto3" json:"group,omitempty"`
+	Key   string `protobuf:"bytes,2,opt,name=key,proto3" json:"key,omitempty"`
+}
+
+func (m *Request) Reset()         { *m = Request{} }
+func (m *Request) String() string { return proto.CompactTextString(m) }
+func (*Request) ProtoMessage()    {}
+
+func (m *Request) GetGroup() string {
+	if m != nil {
+		return m.Group
+	}
+	return ""
+}
+
+func (m *Request) GetKey() string {
+	if m != nil {
+		return m.Key
+	}
+	return ""
+}
+
+// Response 表示一次缓存查询的返回结果
+type Response struct {
+	Value []byte `protobuf:"bytes,1,opt,name=value,proto3" json:"value,omitempty"`
+	// TtlSeconds 是该值在 owner 节点上的剩余存活时间（秒），0 表示永不过期
+	TtlSeconds int64 `protobuf:"varint,2,opt,name=ttl_seconds,json=ttlSeconds,proto3" json:"ttl_seconds,omitempty"`
+	// Version 是该值最近一次写入时打上的单调递增版本号，供写失效通知比较新旧
+	Version int64 `protobuf:"varint,3,opt,name=version,proto3" json:"version,omitempty"`
+}
+
+func (m *Response) Reset()         { *m = Response{} }
+func (m *Response) String() string { return proto.CompactTextString(m) }
+func (*Response) ProtoMessage()    {}
+
+func (m *Response) GetValue() []byte {
+	if m != nil {
+		return m.Value
+	}
+	return nil
+}
+
+func (m *Response) GetTtlSeconds() int64 {
+	if m != nil {
+		return m.TtlSeconds
+	}
+	return 0
+}
+
+func (m *Response) GetVersion() int64 {
+	if m != nil {
+		return m.Version
+	}
+	return 0
+}
+
+// BatchRequest 表示一次批量缓存查询请求，服务端会对 Keys 中的每个 key
+// 通过 BatchGet 流式 RPC 按序逐个返回 Response
+type BatchRequest struct {
+	Group string   `protobuf:"bytes,1,opt,name=group,proto3" json:"group,omitempty"`
+	Keys  []string `protobuf:"bytes,2,rep,name=keys,proto3" json:"keys,omitempty"`
+}
+
+func (m *BatchRequest) Reset()         { *m = BatchRequest{} }
+func (m *BatchRequest) String() string { return proto.CompactTextString(m) }
+func (*BatchRequest) ProtoMessage()    {}
+
+func (m *BatchRequest) GetGroup() string {
+	if m != nil {
+		return m.Group
+	}
+	return ""
+}
+
+func (m *BatchRequest) GetKeys() []string {
+	if m != nil {
+		return m.Keys
+	}
+	return nil
+}
@@ -0,0 +1,149 @@
+// 本文件是 Cache 服务的 gRPC 客户端/服务端代码，对应 rqcachepb.proto 中的
+// service Cache。同样是手写的，不经 protoc-gen-go-grpc 生成。
+package rqcachepb
+
+import (
+	context "context"
+	fmt "fmt"
+
+	grpc "google.golang.org/grpc"
+)
+
+// CacheClient 是 Cache 服务的客户端接口
+type CacheClient interface {
+	Get(ctx context.Context, in *Request, opts ...grpc.CallOption) (*Response, error)
+	BatchGet(ctx context.Context, in *BatchRequest, opts ...grpc.CallOption) (Cache_BatchGetClient, error)
+}
+
+type cacheClient struct {
+	cc grpc.ClientConnInterface
+}
+
+// NewCacheClient 基于一个已建立的 grpc.ClientConn 创建 CacheClient
+func NewCacheClient(cc grpc.ClientConnInterface) CacheClient {
+	return &cacheClient{cc}
+}
+
+func (c *cacheClient) Get(ctx context.Context, in *Request, opts ...grpc.CallOption) (*Response, error) {
+	out := new(Response)
+	if err := c.cc.Invoke(ctx, "/rqcachepb.Cache/Get", in, out, opts...); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cacheClient) BatchGet(ctx context.Context, in *BatchRequest, opts ...grpc.CallOption) (Cache_BatchGetClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Cache_ServiceDesc.Streams[0], "/rqcachepb.Cache/BatchGet", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &cacheBatchGetClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// Cache_BatchGetClient 用于流式接收 BatchGet 按序返回的多个 Response
+type Cache_BatchGetClient interface {
+	Recv() (*Response, error)
+	grpc.ClientStream
+}
+
+type cacheBatchGetClient struct {
+	grpc.ClientStream
+}
+
+func (x *cacheBatchGetClient) Recv() (*Response, error) {
+	m := new(Response)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// CacheServer 是 Cache 服务需要实现的服务端接口
+type CacheServer interface {
+	Get(context.Context, *Request) (*Response, error)
+	BatchGet(*BatchRequest, Cache_BatchGetServer) error
+}
+
+// UnimplementedCacheServer 可以被匿名嵌入到具体实现中，为尚未实现的方法
+// 提供默认报错，便于以后新增 RPC 时保持向前兼容
+type UnimplementedCacheServer struct{}
+
+func (UnimplementedCacheServer) Get(context.Context, *Request) (*Response, error) {
+	return nil, fmt.Errorf("method Get not implemented")
+}
+
+func (UnimplementedCacheServer) BatchGet(*BatchRequest, Cache_BatchGetServer) error {
+	return fmt.Errorf("method BatchGet not implemented")
+}
+
+// RegisterCacheServer 将 srv 注册到 s 上，对外暴露 Cache 服务
+func RegisterCacheServer(s grpc.ServiceRegistrar, srv CacheServer) {
+	s.RegisterService(&Cache_ServiceDesc, srv)
+}
+
+func _Cache_Get_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Request)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CacheServer).Get(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/rqcachepb.Cache/Get",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CacheServer).Get(ctx, req.(*Request))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Cache_BatchGet_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(BatchRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(CacheServer).BatchGet(m, &cacheBatchGetServer{stream})
+}
+
+// Cache_BatchGetServer 用于流式发送 BatchGet 的多个 Response
+type Cache_BatchGetServer interface {
+	Send(*Response) error
+	grpc.ServerStream
+}
+
+type cacheBatchGetServer struct {
+	grpc.ServerStream
+}
+
+func (x *cacheBatchGetServer) Send(m *Response) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+// Cache_ServiceDesc 描述了 Cache 服务，供 grpc.Server 注册时使用
+var Cache_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "rqcachepb.Cache",
+	HandlerType: (*CacheServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Get",
+			Handler:    _Cache_Get_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "BatchGet",
+			Handler:       _Cache_BatchGet_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "rqcachepb.proto",
+}
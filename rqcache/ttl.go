@@ -0,0 +1,28 @@
+package rqcache
+
+import "time"
+
+// ttlValue 在 ByteView 基础上附加一个过期时间和写入版本号。expiresAt 使缓存
+// 条目可以独立于所在 lru.Policy 的淘汰顺序而主动过期，为零值表示永不过期；
+// version 供写失效通知（cache.invalidate）判断本地副本是否已经过时。
+type ttlValue struct {
+	ByteView
+	expiresAt time.Time
+	version   int64
+}
+
+// expired 判断该值相对 now 是否已经过期
+func (v ttlValue) expired(now time.Time) bool {
+	return !v.expiresAt.IsZero() && now.After(v.expiresAt)
+}
+
+// remainingTTL 返回 expiresAt 相对当前时间的剩余存活时间，零值或已过期均返回 0
+func remainingTTL(expiresAt time.Time) time.Duration {
+	if expiresAt.IsZero() {
+		return 0
+	}
+	if d := time.Until(expiresAt); d > 0 {
+		return d
+	}
+	return 0
+}